@@ -0,0 +1,140 @@
+package geecache
+
+import (
+	"Group_Cache/geecache/lru"
+	"sync"
+	"time"
+)
+
+// invalidationWindow 限定 epoch 书签在 keyEpoch 中保留的时间：只要这个窗口比一次
+// getLocally/getFromPeer 实际可能花费的最长时间长，就足够让所有真正可能与某次
+// Invalidate 竞争的写入在书签过期前完成检查；过了这个窗口書签会被自动回收，
+// 不会让 keyEpoch 随着失效过的 key 无限增长。
+const invalidationWindow = 5 * time.Minute
+
+// invalidationWindowCap 是 keyEpoch 的容量预算，双重兜底。lru 按 len(key)+1
+// 计算每个书签的占用，所以能同时保留的书签数量随 key 的长度变少。
+const invalidationWindowCap = 10000
+
+// epochMark 是 keyEpoch 里书签的值类型，只是对 int64 计数器的包装，
+// 实现 lru.Value 接口以便复用现有的 lru.Cache 做 TTL/容量驱动的自动回收。
+type epochMark int64
+
+func (epochMark) Len() int { return 1 }
+
+// cache 对 lru.Cache 进行了封装，使其支持并发读写，lru.Cache 本身不是并发安全的。
+type cache struct {
+	mu         sync.Mutex
+	lru        *lru.Cache
+	cacheBytes int64
+	// ttl 是该 cache 中条目的默认过期时间，0 表示不过期，来自 geecache.WithTTL。
+	ttl time.Duration
+
+	// keyEpoch/globalEpoch 用来识别"这次要写入的数据是在哪一次失效之前取到的"：
+	// Invalidate(key) 让 keyEpoch[key] 前进一步，InvalidateAll 让 globalEpoch 前进
+	// 一步。addIfCurrent 在加锁之后先比较调用方快照的 epoch 和当前 epoch 是否一致，
+	// 一致才真正写入 lru，检查和写入处于同一个临界区内，不会留下"检查通过但写入前
+	// 被 Invalidate"的竞态窗口。keyEpoch 借助 lru.Cache 自身的 TTL 能力自动回收，
+	// 避免为所有失效过的 key 永久保留书签。
+	keyEpoch *lru.Cache
+	// globalEpoch 在每次 InvalidateAll 时自增，语义同 keyEpoch，但覆盖所有 key。
+	globalEpoch int64
+}
+
+// add 向缓存中新增一条记录，lru 延迟初始化是为了减少内存占用，提高性能。
+// ttl 可选，传入时覆盖 cache 的默认过期时间，不传则沿用 cache.ttl。
+func (c *cache) add(key string, value ByteView, ttl ...time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(key, value, ttl...)
+}
+
+func (c *cache) addLocked(key string, value ByteView, ttl ...time.Duration) {
+	if c.lru == nil {
+		c.lru = lru.New(c.cacheBytes, c.ttl, nil)
+	}
+	c.lru.Add(key, value, ttl...)
+}
+
+// get 根据 key 查找缓存，nil 的 lru 视为未命中。
+func (c *cache) get(key string) (value ByteView, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		return
+	}
+	if v, ok := c.lru.Get(key); ok {
+		return v.(ByteView), ok
+	}
+	return
+}
+
+// remove 把 key 从缓存中删除，lru 为 nil 时说明还没有任何条目，直接忽略。
+func (c *cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		return
+	}
+	c.lru.Remove(key)
+}
+
+// clear 清空整个缓存。
+func (c *cache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		return
+	}
+	c.lru.Clear()
+}
+
+// bumpKeyEpoch 让 key 对应的 epoch 前进一步，在 Invalidate(key) 时调用。
+func (c *cache) bumpKeyEpoch(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := epochMark(c.keyGenLocked(key) + 1)
+	if c.keyEpoch == nil {
+		c.keyEpoch = lru.New(invalidationWindowCap, invalidationWindow, nil)
+	}
+	c.keyEpoch.Add(key, next)
+}
+
+// bumpGlobalEpoch 让所有 key 的 epoch 前进一步，在 InvalidateAll 时调用。
+func (c *cache) bumpGlobalEpoch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.globalEpoch++
+}
+
+// generation 返回 key 当前的 epoch 快照：key 自身被 Invalidate 的次数，以及全局
+// 被 InvalidateAll 的次数。调用方应当在发起一次可能耗时的加载之前记下这对值，
+// 写回缓存时交给 addIfCurrent 重新核对。
+func (c *cache) generation(key string) (keyGen, globalGen int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.keyGenLocked(key), c.globalEpoch
+}
+
+func (c *cache) keyGenLocked(key string) int64 {
+	if c.keyEpoch == nil {
+		return 0
+	}
+	if v, ok := c.keyEpoch.Get(key); ok {
+		return int64(v.(epochMark))
+	}
+	return 0
+}
+
+// addIfCurrent 只有在 key 的 epoch 仍然等于调用方快照下来的 startKeyGen/
+// startGlobalGen 时才会真正写入；检查和写入在同一把 mu 下完成，避免一次较慢的
+// 加载在"检查通过"和"实际写入"之间被 Invalidate/InvalidateAll 抢先，从而把已经
+// 失效的数据又悄悄写回缓存。
+func (c *cache) addIfCurrent(key string, value ByteView, startKeyGen, startGlobalGen int64, ttl ...time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.keyGenLocked(key) != startKeyGen || c.globalEpoch != startGlobalGen {
+		return
+	}
+	c.addLocked(key, value, ttl...)
+}
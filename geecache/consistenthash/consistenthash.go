@@ -80,11 +80,16 @@ func (m *Map) Get(key string) string {
 	return m.hashMap[m.keys[idx%len(m.keys)]]
 }
 
-// Remove 删除节点操作
+// Remove 删除节点操作。对从未 Add 过、或已经被移除过的 key（调用方传入的
+// 任意字符串都可能出现这种情况），sort.SearchInts 找不到对应的 hash 时会
+// 返回 len(m.keys)，此时必须跳过这个虚拟节点，否则切片越界会直接 panic。
 func (m *Map) Remove(key string) {
 	for i := 0; i < m.replicas; i++ {
 		hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
 		idx := sort.SearchInts(m.keys, hash)
+		if idx >= len(m.keys) || m.keys[idx] != hash {
+			continue
+		}
 		m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
 		delete(m.hashMap, hash)
 	}
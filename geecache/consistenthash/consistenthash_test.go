@@ -0,0 +1,72 @@
+package consistenthash
+
+import (
+	"strconv"
+	"testing"
+)
+
+// TestAddRemapsOnlyAFraction 验证一致性哈希的核心特性：
+// 新增一个节点后，只有大约 1/N（N 为节点总数）的 key 会被重新映射，
+// 其余 key 的归属节点应当保持不变。
+func TestAddRemapsOnlyAFraction(t *testing.T) {
+	m := New(50, nil)
+	m.Add("peer1", "peer2", "peer3")
+
+	const numKeys = 10000
+	before := make(map[string]string, numKeys)
+	for i := 0; i < numKeys; i++ {
+		key := strconv.Itoa(i)
+		before[key] = m.Get(key)
+	}
+
+	m.Add("peer4")
+
+	remapped := 0
+	for i := 0; i < numKeys; i++ {
+		key := strconv.Itoa(i)
+		if m.Get(key) != before[key] {
+			remapped++
+		}
+	}
+
+	// 4 个节点时期望大约 1/4 的 key 被重新映射，留出足够的容差。
+	ratio := float64(remapped) / float64(numKeys)
+	if ratio < 0.1 || ratio > 0.4 {
+		t.Fatalf("expected roughly 1/4 of keys to remap after adding a node, got ratio %v (%d/%d)", ratio, remapped, numKeys)
+	}
+}
+
+// TestRemove 验证移除节点后，该节点对应的虚拟节点从环上消失。
+func TestRemove(t *testing.T) {
+	m := New(3, func(key []byte) uint32 {
+		i, _ := strconv.Atoi(string(key))
+		return uint32(i)
+	})
+
+	m.Add("6", "4", "2")
+	m.Remove("6")
+
+	if _, ok := m.hashMap[6]; ok {
+		t.Fatal("expected virtual node for peer 6 to be removed")
+	}
+	if got := m.Get("5"); got != "2" {
+		t.Fatalf("expected key 5 to map to peer 2 after removing peer 6, got %v", got)
+	}
+}
+
+// TestRemoveUnknownPeerDoesNotPanic 验证移除一个从未 Add 过、或者已经被移除过
+// 的 peer 不会让 sort.SearchInts 命中"未找到"的情况时越界 panic。
+func TestRemoveUnknownPeerDoesNotPanic(t *testing.T) {
+	m := New(50, nil)
+	m.Add("peer1", "peer2")
+
+	m.Remove("never-added")
+
+	// 再次移除同一个 peer，触发"已经被移除过"的场景。
+	m.Remove("peer1")
+	m.Remove("peer1")
+
+	if got := m.Get("anykey"); got != "peer2" {
+		t.Fatalf("expected remaining peer2 to serve all keys, got %v", got)
+	}
+}
@@ -12,12 +12,22 @@ package geecache
 */
 
 import (
+	pb "Group_Cache/geecache/geecachepb"
 	"Group_Cache/geecache/singleflight"
 	"fmt"
 	"log"
+	"math/rand"
 	"sync"
+	"time"
 )
 
+// hotCacheRatio 决定 hotCache 相对 mainCache 预算的大小，约为总预算的 1/8。
+const hotCacheRatio = 8
+
+// hotCacheProbability 是远程节点命中后写入 hotCache 的概率，约为 1/10，
+// 避免每个节点都缓存每一个 key，只吸收真正的热点。
+const hotCacheProbability = 10
+
 /*
 	一个 Group 可以认为是一个缓存的命名空间，
 	每个 Group 拥有一个唯一的名称 name。比如可以创建三个 Group，
@@ -27,12 +37,19 @@ type Group struct {
 	name string
 	// 缓存未命中时获取源数据的回调(callback)。
 	getter Getter
-	// 一开始实现的并发缓存。
+	// mainCache 存储按一致性哈希本节点应当承载的 key。
 	mainCache cache
+	// hotCache 存储从其他节点获取、但在本节点访问也很频繁的 key，
+	// 用来吸收热点，避免每次都打到远程节点。
+	hotCache cache
 	// 用于根据传入的 key 选择相应节点 PeerGetter
 	peers PeerPicker
 	// 使用 singleflight.Group 确保每个key只获取一次
 	loader *singleflight.Group
+	// stats 记录 Get/命中/远程加载等运行时指标
+	stats Stats
+	// ttl 是 mainCache/hotCache 条目的默认过期时间，由 WithTTL 配置，0 表示不过期。
+	ttl time.Duration
 }
 
 /*
@@ -62,19 +79,35 @@ var (
 	groups = make(map[string]*Group)
 )
 
+// GroupOption 用于配置 NewGroup 创建的 Group，遵循函数式选项模式，方便未来扩展。
+type GroupOption func(*Group)
+
+// WithTTL 为 Group 的 mainCache/hotCache 设置默认过期时间，0（默认值）表示不过期。
+// 这让依赖 DB 等外部数据源的 GetterFunc 可以限定缓存的最大 staleness，
+// 而不必放弃 LRU 本身的内存上限。
+func WithTTL(ttl time.Duration) GroupOption {
+	return func(g *Group) {
+		g.ttl = ttl
+	}
+}
+
 // NewGroup 实例化 Group，并且将 group 存储在全局变量 groups 中。
-func NewGroup(name string, cacheBytes int64, getter Getter) *Group {
+func NewGroup(name string, cacheBytes int64, getter Getter, opts ...GroupOption) *Group {
 	if getter == nil {
 		panic("nil Getter")
 	}
 	mu.Lock()
 	defer mu.Unlock()
 	g := &Group{
-		name:      name,
-		getter:    getter,
-		mainCache: cache{cacheBytes: cacheBytes},
-		loader:    &singleflight.Group{},
+		name:   name,
+		getter: getter,
+		loader: &singleflight.Group{},
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	g.mainCache = cache{cacheBytes: cacheBytes, ttl: g.ttl}
+	g.hotCache = cache{cacheBytes: cacheBytes / hotCacheRatio, ttl: g.ttl}
 	groups[name] = g
 	return g
 }
@@ -87,13 +120,33 @@ func GetGroup(name string) *Group {
 	return g
 }
 
+// ServeGet 是响应其他节点缓存查询的公共入口：按 req 中的 group 找到对应 Group，
+// 再按 key 查找缓存值并封装成 pb.Response。grpcpool 的 gRPC handler 直接调用它；
+// HTTPPool.ServeHTTP 自行查找 group 后调用 serveGet，查找逻辑只实现一次。
+func ServeGet(req *pb.Request) (*pb.Response, error) {
+	group := GetGroup(req.GetGroup())
+	if group == nil {
+		return nil, fmt.Errorf("no such group: %s", req.GetGroup())
+	}
+	return group.serveGet(req.GetKey())
+}
+
+// serveGet 在本地查找 key 对应的缓存值并封装成 pb.Response。
+func (g *Group) serveGet(key string) (*pb.Response, error) {
+	view, err := g.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Response{Value: view.ByteSlice()}, nil
+}
+
 /*
 	Get 方法实现了流程 ⑴ 和 ⑶。2
 	流程 ⑴ ：从 mainCache 中查找缓存，如果存在则返回缓存值。
 	流程 ⑶ ：缓存不存在，则调用 load 方法，load 调用 getLocally
 	（分布式场景下会调用 getFromPeer 从其他节点获取），getLocally
 	调用用户回调函数 g.getter.Get() 获取源数据，并且将源数据添加到缓存
-	mainCache 中（通过 populateCache 方法）
+	mainCache 中（通过 cache.addIfCurrent 方法）
 */
 func (g *Group) Get(key string) (ByteView, error) {
 
@@ -101,9 +154,19 @@ func (g *Group) Get(key string) (ByteView, error) {
 		return ByteView{}, fmt.Errorf("key is required")
 	}
 
+	g.stats.addGets()
+
 	// 缓存命中
 	if v, ok := g.mainCache.get(key); ok {
 		log.Println("[GeeCache] hit")
+		g.stats.addCacheHits()
+		return v, nil
+	}
+
+	// hotCache 命中，说明这是一个从其他节点拉取过的热点 key
+	if v, ok := g.hotCache.get(key); ok {
+		log.Println("[GeeCache] hot hit")
+		g.stats.addCacheHits()
 		return v, nil
 	}
 
@@ -111,6 +174,11 @@ func (g *Group) Get(key string) (ByteView, error) {
 	return g.load(key)
 }
 
+// Stats 返回当前 Group 的运行时指标快照，供运维观察缓存命中率、远程加载分布等。
+func (g *Group) Stats() Stats {
+	return g.stats.snapshot()
+}
+
 // RegisterPeers 实现了 PeerPicker 接口的 HTTPPool 注入到 Group 中
 func (g *Group) RegisterPeers(peers PeerPicker) {
 	if g.peers != nil {
@@ -119,51 +187,111 @@ func (g *Group) RegisterPeers(peers PeerPicker) {
 	g.peers = peers
 }
 
+// Invalidate 删除本地 mainCache/hotCache 中的 key，并把删除操作广播给集群内
+// 所有节点，使其他节点上残留的副本（尤其是 hotCache）也不会再返回旧值。
+func (g *Group) Invalidate(key string) {
+	g.mainCache.remove(key)
+	g.mainCache.bumpKeyEpoch(key)
+	g.hotCache.remove(key)
+	g.hotCache.bumpKeyEpoch(key)
+	// 丢弃可能仍在进行中的 singleflight 登记，这样下一次 Get 会发起全新的 Do/DoChan，
+	// 而不是等待或复用一次可能已经不再代表最新数据的调用。
+	g.loader.Forget(key)
+	g.invalidatePeers(&pb.Request{Group: g.name, Key: key})
+}
+
+// InvalidateAll 清空本地 mainCache/hotCache 的全部内容，并广播给集群内所有节点。
+func (g *Group) InvalidateAll() {
+	g.mainCache.clear()
+	g.mainCache.bumpGlobalEpoch()
+	g.hotCache.clear()
+	g.hotCache.bumpGlobalEpoch()
+	g.invalidatePeers(&pb.Request{Group: g.name})
+}
+
+// invalidatePeers 把失效请求广播给所有已知节点，节点发现/注册的传输层（HTTPPool 等）
+// 只有同时实现 PeerIterator 和 PeerInvalidator 才会参与，其余传输层会被安静地跳过。
+func (g *Group) invalidatePeers(req *pb.Request) {
+	iterator, ok := g.peers.(PeerIterator)
+	if !ok {
+		return
+	}
+	for _, peer := range iterator.AllPeers() {
+		invalidator, ok := peer.(PeerInvalidator)
+		if !ok {
+			continue
+		}
+		if err := invalidator.Invalidate(req); err != nil {
+			log.Println("[GeeCache] Failed to invalidate peer", err)
+		}
+	}
+}
+
 // 从远程节点获取数据,若失败,则调用回调函数从数据库获取
 func (g *Group) load(key string) (value ByteView, err error) {
-	// 使用 g.loader.Do 包裹，确保了并发场景下针对相同的 key，load 过程只会调用一次
-	viewi, err := g.loader.Do(key, func() (interface{}, error) {
+	// 记下发起这次 load 时 mainCache/hotCache 各自的 epoch 快照，write-back 时交给
+	// addIfCurrent 在加锁后重新核对，如果其间发生过针对这个 key（或全部 key）的
+	// Invalidate/InvalidateAll，就放弃写入，防止一次慢请求在失效之后才把过期值
+	// 写回去。mainCache 和 hotCache 各自维护 epoch，因为一次 load 至多写入其中一个。
+	startMainKeyGen, startMainGlobalGen := g.mainCache.generation(key)
+	startHotKeyGen, startHotGlobalGen := g.hotCache.generation(key)
+
+	// 使用 g.loader.DoChan 包裹，确保了并发场景下针对相同的 key，load 过程只会调用一次，
+	// 并通过 Result.Shared 知道这次请求是否是与别的调用者合并的，用于统计 LoaderHits。
+	res := <-g.loader.DoChan(key, func() (interface{}, error) {
 		// 从远程节点获取 peers为哈希环
 		if g.peers != nil {
 			if peer, ok := g.peers.PickPeer(key); ok {
 				if value, err = g.getFromPeer(peer, key); err == nil {
+					g.stats.addPeerLoads()
+					// 只有一部分概率把远程拉取的值缓存到本地 hotCache，
+					// 避免每个节点都缓存每一个 key 造成重复浪费。
+					if rand.Intn(hotCacheProbability) == 0 {
+						g.hotCache.addIfCurrent(key, value, startHotKeyGen, startHotGlobalGen)
+					}
 					return value, nil
 				}
+				g.stats.addPeerErrors()
 				log.Println("[GeeCache] Failed to get from peer", err)
 			}
 		}
 		// 失败 则回调函数
-		return g.getLocally(key)
+		return g.getLocally(key, startMainKeyGen, startMainGlobalGen)
 	})
 
-	if err == nil {
-		return viewi.(ByteView), nil
+	if res.Shared {
+		g.stats.addLoaderHits()
 	}
-	return
+	if res.Err == nil {
+		return res.Val.(ByteView), nil
+	}
+	return ByteView{}, res.Err
 }
 
 // getFromPeer 实现了 PeerGetter 接口的 httpGetter 从访问远程节点，获取缓存值。
 func (g *Group) getFromPeer(peer PeerGetter, key string) (ByteView, error) {
+	req := &pb.Request{
+		Group: g.name,
+		Key:   key,
+	}
+	res := &pb.Response{}
 	// 返回远程节点的缓存值(http.get跳到了serverHTTP 里面返回远程节点缓存值)
-	bytes, err := peer.Get(g.name, key)
+	err := peer.Get(req, res)
 	if err != nil {
 		return ByteView{}, err
 	}
-	return ByteView{b: bytes}, nil
+	return ByteView{b: res.Value}, nil
 }
 
 // 获得源数据
-func (g *Group) getLocally(key string) (ByteView, error) {
+func (g *Group) getLocally(key string, startKeyGen, startGlobalGen int64) (ByteView, error) {
 	bytes, err := g.getter.Get(key)
 	if err != nil {
 		return ByteView{}, err
 	}
+	g.stats.addLocalLoads()
 	value := ByteView{b: cloneBytes(bytes)}
-	g.populateCache(key, value)
+	// 本节点是该 key 按一致性哈希的权威节点，写入 mainCache。
+	g.mainCache.addIfCurrent(key, value, startKeyGen, startGlobalGen)
 	return value, nil
 }
-
-// 将源数据添加到缓存 mainCache 中
-func (g *Group) populateCache(key string, value ByteView) {
-	g.mainCache.add(key, value)
-}
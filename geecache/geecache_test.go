@@ -0,0 +1,276 @@
+package geecache
+
+import (
+	pb "Group_Cache/geecache/geecachepb"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestInvalidateDuringInFlightLoadDoesNotResurrectStaleValue 覆盖一个竞态场景：
+// Invalidate(key) 在一次仍在执行中的 getLocally 返回之前就运行了。修复前，
+// 这次慢请求会在 Invalidate 之后才把过期值写回 mainCache，这里验证修复后不会发生。
+func TestInvalidateDuringInFlightLoadDoesNotResurrectStaleValue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	g := NewGroup(fmt.Sprintf("test-invalidate-race-%d", 1), 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		close(started)
+		<-release
+		return []byte("stale-value"), nil
+	}))
+
+	done := make(chan ByteView, 1)
+	go func() {
+		v, err := g.Get("k")
+		if err != nil {
+			t.Error(err)
+		}
+		done <- v
+	}()
+
+	<-started
+	g.Invalidate("k")
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Get did not return in time")
+	}
+
+	if _, ok := g.mainCache.get("k"); ok {
+		t.Fatal("expected the in-flight load to not resurrect the invalidated key in mainCache")
+	}
+}
+
+// TestNewGroupSizesHotCacheAsFractionOfMainCache 验证 hotCache 的预算是
+// mainCache 的 1/hotCacheRatio，这是 hotCache 吸收热点、又不会喧宾夺主的前提。
+func TestNewGroupSizesHotCacheAsFractionOfMainCache(t *testing.T) {
+	g := NewGroup("test-hotcache-ratio", 8<<10, GetterFunc(func(key string) ([]byte, error) {
+		return []byte("v"), nil
+	}))
+
+	if g.mainCache.cacheBytes != 8<<10 {
+		t.Fatalf("expected mainCache.cacheBytes = %d, got %d", 8<<10, g.mainCache.cacheBytes)
+	}
+	if want := int64(8<<10) / hotCacheRatio; g.hotCache.cacheBytes != want {
+		t.Fatalf("expected hotCache.cacheBytes = %d (1/%d of mainCache), got %d", want, hotCacheRatio, g.hotCache.cacheBytes)
+	}
+}
+
+// TestStatsTracksLocalLoad 验证本地回源（无 peers）时 Stats 中的
+// Gets/CacheHits/LocalLoads 按预期增长，PeerLoads 保持为 0。
+func TestStatsTracksLocalLoad(t *testing.T) {
+	g := NewGroup("test-stats-local", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		return []byte("value-of-" + key), nil
+	}))
+
+	if _, err := g.Get("k"); err != nil {
+		t.Fatal(err)
+	}
+	// 第二次访问应当命中 mainCache，而不是再次触发本地加载。
+	if _, err := g.Get("k"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := g.Stats()
+	if stats.Gets != 2 {
+		t.Fatalf("expected Gets = 2, got %d", stats.Gets)
+	}
+	if stats.LocalLoads != 1 {
+		t.Fatalf("expected LocalLoads = 1, got %d", stats.LocalLoads)
+	}
+	if stats.CacheHits != 1 {
+		t.Fatalf("expected CacheHits = 1 (the second Get), got %d", stats.CacheHits)
+	}
+	if stats.PeerLoads != 0 {
+		t.Fatalf("expected PeerLoads = 0, got %d", stats.PeerLoads)
+	}
+}
+
+// fakePeerPicker 是一个只实现 PeerPicker 的最小 mock，把所有 key 都路由到同一个
+// fakePeerGetter，用来在不依赖真实 HTTP/gRPC 传输层的情况下模拟一次远程命中。
+type fakePeerPicker struct {
+	peer PeerGetter
+}
+
+func (f fakePeerPicker) PickPeer(key string) (PeerGetter, bool) {
+	return f.peer, true
+}
+
+// fakePeerGetter 模拟一次成功的远程节点查询。
+type fakePeerGetter struct {
+	value []byte
+}
+
+func (f fakePeerGetter) Get(in *pb.Request, out *pb.Response) error {
+	out.Value = f.value
+	return nil
+}
+
+// TestStatsTracksPeerLoad 验证在 PickPeer 命中远程节点时，Stats 中的 PeerLoads
+// 增长而 LocalLoads 保持为 0，本地回调函数不会被调用。
+func TestStatsTracksPeerLoad(t *testing.T) {
+	g := NewGroup("test-stats-peer", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		t.Fatal("getter should not be called when a peer hit succeeds")
+		return nil, nil
+	}))
+	g.RegisterPeers(fakePeerPicker{peer: fakePeerGetter{value: []byte("from-peer")}})
+
+	v, err := g.Get("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "from-peer" {
+		t.Fatalf("expected value %q, got %q", "from-peer", v.String())
+	}
+
+	stats := g.Stats()
+	if stats.PeerLoads != 1 {
+		t.Fatalf("expected PeerLoads = 1, got %d", stats.PeerLoads)
+	}
+	if stats.LocalLoads != 0 {
+		t.Fatalf("expected LocalLoads = 0, got %d", stats.LocalLoads)
+	}
+}
+
+// TestInvalidateRemovesKeyFromBothCaches 验证非竞态的基本情形：Invalidate(key)
+// 之后，mainCache 和 hotCache 都不再持有该 key，下一次 Get 必须重新回源。
+func TestInvalidateRemovesKeyFromBothCaches(t *testing.T) {
+	calls := 0
+	g := NewGroup("test-invalidate-basic", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		calls++
+		return []byte(fmt.Sprintf("v%d", calls)), nil
+	}))
+
+	if _, err := g.Get("k"); err != nil {
+		t.Fatal(err)
+	}
+	g.hotCache.add("k", ByteView{b: []byte("hot")})
+
+	g.Invalidate("k")
+
+	if _, ok := g.mainCache.get("k"); ok {
+		t.Fatal("expected mainCache to no longer hold the invalidated key")
+	}
+	if _, ok := g.hotCache.get("k"); ok {
+		t.Fatal("expected hotCache to no longer hold the invalidated key")
+	}
+
+	v, err := g.Get("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "v2" || calls != 2 {
+		t.Fatalf("expected Invalidate to force a fresh load, got value %q after %d calls", v.String(), calls)
+	}
+}
+
+// TestInvalidateAllClearsBothCaches 验证 InvalidateAll 会清空 mainCache 和
+// hotCache 的全部内容，而不只是某一个 key。
+func TestInvalidateAllClearsBothCaches(t *testing.T) {
+	g := NewGroup("test-invalidateall-basic", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		return []byte("v"), nil
+	}))
+
+	if _, err := g.Get("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get("k2"); err != nil {
+		t.Fatal(err)
+	}
+	g.hotCache.add("k3", ByteView{b: []byte("hot")})
+
+	g.InvalidateAll()
+
+	for _, key := range []string{"k1", "k2"} {
+		if _, ok := g.mainCache.get(key); ok {
+			t.Fatalf("expected mainCache to be empty after InvalidateAll, still has %q", key)
+		}
+	}
+	if _, ok := g.hotCache.get("k3"); ok {
+		t.Fatal("expected hotCache to be empty after InvalidateAll")
+	}
+}
+
+// fakePeerInvalidatorGetter 同时实现 PeerGetter 和 PeerInvalidator，记录收到的
+// Invalidate 请求，用于验证 invalidatePeers 真的把失效请求广播给了每一个节点。
+type fakePeerInvalidatorGetter struct {
+	invalidated *[]*pb.Request
+}
+
+func (f fakePeerInvalidatorGetter) Get(in *pb.Request, out *pb.Response) error {
+	return fmt.Errorf("Get should not be called in this test")
+}
+
+func (f fakePeerInvalidatorGetter) Invalidate(in *pb.Request) error {
+	*f.invalidated = append(*f.invalidated, in)
+	return nil
+}
+
+// fakePeerIterator 同时实现 PeerPicker 和 PeerIterator，AllPeers 返回固定的节点列表。
+type fakePeerIterator struct {
+	peers []PeerGetter
+}
+
+func (f fakePeerIterator) PickPeer(key string) (PeerGetter, bool) {
+	return nil, false
+}
+
+func (f fakePeerIterator) AllPeers() []PeerGetter {
+	return f.peers
+}
+
+// TestInvalidatePeersBroadcastsToAllPeers 验证 Invalidate 会把失效请求广播给
+// PeerIterator.AllPeers 返回的每一个同时实现 PeerInvalidator 的节点。
+func TestInvalidatePeersBroadcastsToAllPeers(t *testing.T) {
+	var gotA, gotB []*pb.Request
+	peerA := fakePeerInvalidatorGetter{invalidated: &gotA}
+	peerB := fakePeerInvalidatorGetter{invalidated: &gotB}
+
+	g := NewGroup("test-invalidate-peers", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		return []byte("v"), nil
+	}))
+	g.RegisterPeers(fakePeerIterator{peers: []PeerGetter{peerA, peerB}})
+
+	g.Invalidate("k")
+
+	for name, got := range map[string][]*pb.Request{"peerA": gotA, "peerB": gotB} {
+		if len(got) != 1 {
+			t.Fatalf("expected %s to receive exactly one Invalidate call, got %d", name, len(got))
+		}
+		if got[0].Group != g.name || got[0].Key != "k" {
+			t.Fatalf("expected %s to receive {Group: %q, Key: %q}, got %+v", name, g.name, "k", got[0])
+		}
+	}
+}
+
+// TestWithTTLExpiresCacheEntries 端到端验证 WithTTL 确实从 NewGroup 一路传到了
+// mainCache 背后的 lru.Cache：TTL 过去之后，Get 必须重新触发一次本地加载，
+// 而不是继续把 mainCache 里的旧值当作命中返回。
+func TestWithTTLExpiresCacheEntries(t *testing.T) {
+	calls := 0
+	g := NewGroup("test-with-ttl", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		calls++
+		return []byte(fmt.Sprintf("v%d", calls)), nil
+	}), WithTTL(50*time.Millisecond))
+
+	v1, err := g.Get("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1.String() != "v1" || calls != 1 {
+		t.Fatalf("expected first Get to load once, got %q after %d calls", v1.String(), calls)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	v2, err := g.Get("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.String() != "v2" || calls != 2 {
+		t.Fatalf("expected TTL expiry to force a fresh load, got %q after %d calls", v2.String(), calls)
+	}
+}
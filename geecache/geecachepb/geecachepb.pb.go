@@ -0,0 +1,55 @@
+// 本文件手写维护，对应 geecachepb.proto 中定义的消息，不是 protoc-gen-go 的真实输出
+// （沙箱环境没有 protoc 可用）。依赖 github.com/golang/protobuf/proto 的旧版结构体标签
+// 反射完成编解码，缺少当前 protoc-gen-go 会生成的 descriptor/反射信息。修改 .proto 后
+// 需要同步手动更新这里，而不是直接用 protoc 重新生成并覆盖。
+
+package geecachepb
+
+import (
+	proto "github.com/golang/protobuf/proto"
+)
+
+// Request 对应一次跨节点的缓存查询，group 是命名空间，key 是待查询的缓存键。
+type Request struct {
+	Group string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Key   string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *Request) Reset()         { *m = Request{} }
+func (m *Request) String() string { return proto.CompactTextString(m) }
+func (*Request) ProtoMessage()    {}
+
+func (m *Request) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *Request) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+// Response 携带查询到的缓存值，value 为原始字节，由调用方负责解释。
+type Response struct {
+	Value []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *Response) Reset()         { *m = Response{} }
+func (m *Response) String() string { return proto.CompactTextString(m) }
+func (*Response) ProtoMessage()    {}
+
+func (m *Response) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Request)(nil), "geecachepb.Request")
+	proto.RegisterType((*Response)(nil), "geecachepb.Response")
+}
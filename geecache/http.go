@@ -2,7 +2,9 @@ package geecache
 
 import (
 	"Group_Cache/geecache/consistenthash"
+	pb "Group_Cache/geecache/geecachepb"
 	"fmt"
+	"github.com/golang/protobuf/proto"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -45,7 +47,9 @@ type HTTPPool struct {
 	// 因为一个主机上还可能承载其他的服务，加一段 Path 是一个好习惯。
 	// 比如，大部分网站的 API 接口，一般以 /api 作为前缀。
 	basePath string
-	mu       sync.Mutex
+	// PickPeer 只需要读锁，Add/Remove/Set 等变更成员的操作需要写锁，
+	// 避免节点频繁扩缩容时读路径互相阻塞。
+	mu sync.RWMutex
 	// 类型是一致性哈希算法的 Map，用来根据具体的 key 选择节点
 	peers *consistenthash.Map
 	// 映射远程节点 peer(即哈希环的真实节点) 与对应的 httpGetter。每一个远程节点对应一个 httpGetter，
@@ -75,27 +79,56 @@ func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	p.Log("%s,%s", r.Method, r.URL.Path)
 
-	// 约定访问路径格式为 /<basePath>/<groupName>/<key>
+	// 约定访问路径格式为 /<basePath>/<groupName>[/<key>]，DELETE 请求的 key
+	// 可以省略，表示让整个 group 失效。
 	// r.URL.Path 从basePath之后开始分割，分割出两个子串
 	parts := strings.SplitN(r.URL.Path[len(p.basePath):], "/", 2)
-	if len(parts) != 2 {
+	if len(parts) == 0 || parts[0] == "" {
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
 
 	groupName := parts[0]
-	key := parts[1]
+	var key string
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+
 	// 通过 groupName 得到 group 实例
 	group := GetGroup(groupName)
-
 	if group == nil {
 		http.Error(w, "No such group: "+groupName, http.StatusNotFound)
+		return
 	}
 
-	// 再使用 group.Get(key) 获取缓存数据
-	// 即远程节点又走了一遍流程123
-	view, err := group.Get(key)
+	switch r.Method {
+	case http.MethodDelete:
+		if key == "" {
+			group.InvalidateAll()
+		} else {
+			group.Invalidate(key)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+		if key == "" {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
+	// serveGet 是 HTTP/gRPC 共用的查找逻辑，按 key 查找缓存值并封装成 pb.Response
+	res, err := group.serveGet(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 使用 protobuf 编码返回值，减小大体积/二进制数据（例如图片）的序列化开销
+	body, err := proto.Marshal(res)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -104,7 +137,7 @@ func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// 设置http头 key: Content-Type（内容类型) value: application/octet-stream
 	w.Header().Set("Content-Type", "application/octet-stream")
 	// 最终使用 w.Write() 将缓存值作为 httpResponse 的 body 返回
-	w.Write(view.ByteSlice())
+	w.Write(body)
 
 }
 
@@ -115,39 +148,78 @@ type httpGetter struct {
 }
 
 // Get 实现 PeerGetter 接口 ,用于从对应 group 查找缓存值。
-// Get 使用 http.Get() 方式获取返回值，并转换为 []bytes 类型
-func (h *httpGetter) Get(group string, key string) ([]byte, error) {
+// Get 使用 http.Get() 方式获取返回值，并使用 proto.Unmarshal 解析为 out
+func (h *httpGetter) Get(in *pb.Request, out *pb.Response) error {
 	// QueryEscape函数对参数进行转码使之可以安全的用在URL查询里
-	u := fmt.Sprintf("%v%v/%v", h.baseURL, url.QueryEscape(group), url.QueryEscape(key))
+	u := fmt.Sprintf(
+		"%v%v/%v",
+		h.baseURL,
+		url.QueryEscape(in.GetGroup()),
+		url.QueryEscape(in.GetKey()),
+	)
 	// url get请求 使用 http.Get() 方式获取返回值
 	// 这一步直接到了上面的ServeHTTP()
 	// ServeHTTP()返回的就是对于远程节点的缓存值
 	res, err := http.Get(u)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server returned: %v", res.Status)
+		return fmt.Errorf("server returned: %v", res.Status)
 	}
 
 	// 读取所有内容
 	bytes, err := ioutil.ReadAll(res.Body)
 
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %v", err)
+		return fmt.Errorf("reading response body: %v", err)
 	}
 
-	return bytes, nil
+	if err = proto.Unmarshal(bytes, out); err != nil {
+		return fmt.Errorf("decoding response body: %v", err)
+	}
 
+	return nil
+
+}
+
+// Invalidate 实现 PeerInvalidator 接口，向远程节点发送 DELETE 请求，
+// 让对方也清除本地的缓存副本。in.Key 为空表示失效整个 group。
+func (h *httpGetter) Invalidate(in *pb.Request) error {
+	u := fmt.Sprintf("%v%v", h.baseURL, url.QueryEscape(in.GetGroup()))
+	if in.GetKey() != "" {
+		u = fmt.Sprintf("%v/%v", u, url.QueryEscape(in.GetKey()))
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("server returned: %v", res.Status)
+	}
+	return nil
 }
 
 // 确保这个类型实现了这个接口 如果没有实现会报错
-var _ PeerGetter = (*httpGetter)(nil)
+var (
+	_ PeerGetter      = (*httpGetter)(nil)
+	_ PeerInvalidator = (*httpGetter)(nil)
+	_ PeerIterator    = (*HTTPPool)(nil)
+)
 
-// Set 方法实例化了一致性哈希算法，并且添加了传入的节点
+// Set 方法实例化了一致性哈希算法，并且添加了传入的节点。
+// Set 会整体重建哈希环，适合在启动时一次性配置全部节点；
+// 集群运行期间的增删节点请使用 Add/Remove，它们只做增量修改。
 func (p *HTTPPool) Set(peers ...string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -161,12 +233,70 @@ func (p *HTTPPool) Set(peers ...string) {
 	}
 }
 
+// Add 向哈希环中增量添加节点，只有新节点落到环上的 key 会被重新映射，
+// 其余 key 的归属不受影响，支持集群在线扩容。
+func (p *HTTPPool) Add(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		p.peers = consistenthash.New(defaultReplicas, nil)
+		p.httpGetters = make(map[string]*httpGetter)
+	}
+	p.peers.Add(peers...)
+	for _, peer := range peers {
+		if _, ok := p.httpGetters[peer]; !ok {
+			p.httpGetters[peer] = &httpGetter{baseURL: peer + p.basePath}
+		}
+	}
+}
+
+// Remove 将节点从哈希环上摘除，只有原本落在这些节点上的 key 会被重新映射，
+// 支持集群在线缩容而不必重启。
+func (p *HTTPPool) Remove(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return
+	}
+	for _, peer := range peers {
+		p.peers.Remove(peer)
+		delete(p.httpGetters, peer)
+	}
+}
+
+// UsePeerRegistry 订阅一个外部的节点发现源（例如 etcd/consul），
+// 每当成员发生变化，registry 都会回调并把最新的全量节点列表传入，
+// HTTPPool 据此重建哈希环，从而做到集群扩缩容时无需重启。
+func (p *HTTPPool) UsePeerRegistry(r PeerRegistry) error {
+	return r.Subscribe(func(peers []string) {
+		p.Set(peers...)
+	})
+}
+
+// AllPeers 实现 PeerIterator 接口，返回当前已知的全部远程节点，
+// 供 Group.Invalidate 广播失效操作时使用。
+func (p *HTTPPool) AllPeers() []PeerGetter {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	peers := make([]PeerGetter, 0, len(p.httpGetters))
+	for peer, getter := range p.httpGetters {
+		if peer == p.self {
+			continue
+		}
+		peers = append(peers, getter)
+	}
+	return peers
+}
+
 // PickPeer 实现 PeerPicker 接口
 // 用于根据传入的 key 选择相应节点 PeerGetter,返回节点对应的 HTTP 客户端
 func (p *HTTPPool) PickPeer(key string) (PeerGetter, bool) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-		// 查询哈希环返回真实节点peer
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	// 查询哈希环返回真实节点peer
 	if peer := p.peers.Get(key); peer != "" && peer != p.self {
 		p.Log("Pick peer %s", peer)
 		return p.httpGetters[peer], true
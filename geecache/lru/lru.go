@@ -1,6 +1,9 @@
 package lru
 
-import "container/list"
+import (
+	"container/list"
+	"time"
+)
 
 type Cache struct {
 	// 允许使用的最大内存
@@ -13,6 +16,8 @@ type Cache struct {
 	cache map[string]*list.Element
 	// 可选并在清除条目时执行
 	OnEvicted func(key string, value Value)
+	// defaultTTL 是 Add 未显式指定 TTL 时使用的过期时间，0 表示永不过期。
+	defaultTTL time.Duration
 }
 
 // Value :为了值的通用性， 使用接口，Len()值所占的大小
@@ -24,27 +29,39 @@ type Value interface {
 type entry struct {
 	key   string
 	value Value
+	// expireAt 为零值表示该条目没有过期时间。
+	expireAt time.Time
 }
 
-// New :Cache的实例化
-func New(maxBytes int64, OnEvicted func(key string, value Value)) *Cache {
+func (e *entry) expired() bool {
+	return !e.expireAt.IsZero() && time.Now().After(e.expireAt)
+}
+
+// New :Cache的实例化，defaultTTL 为 0 表示条目默认不过期，
+// 可以在 Add 时针对单个 key 传入 TTL 覆盖这个默认值。
+func New(maxBytes int64, defaultTTL time.Duration, OnEvicted func(key string, value Value)) *Cache {
 	return &Cache{
-		maxBytes:  maxBytes,
-		ll:        list.New(),
-		cache:     make(map[string]*list.Element),
-		OnEvicted: OnEvicted,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		cache:      make(map[string]*list.Element),
+		OnEvicted:  OnEvicted,
+		defaultTTL: defaultTTL,
 	}
 }
 
-// Get :根据key进行查找
+// Get :根据key进行查找，如果条目已过期则视为未命中并顺带淘汰它
 func (c *Cache) Get(key string) (value Value, ok bool) {
 	// 双向链表作为队列，队首队尾是相对的，在这里约定 front 为队尾
 	// ele为cache的值，即双向链表对应节点的指针
 	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		if kv.expired() {
+			c.removeElement(ele)
+			return nil, false
+		}
 		// 将找到的元素节点移动至队尾
 		c.ll.MoveToFront(ele)
 		// (*entry)表示将Value转成*entry类型访问
-		kv := ele.Value.(*entry)
 		return kv.value, true
 	}
 	return
@@ -55,21 +72,46 @@ func (c *Cache) RemoveOldest() {
 	// 取到队首节点
 	ele := c.ll.Back()
 	if ele != nil {
-		// 将队首节点从链表中删除。
-		c.ll.Remove(ele)
-		kv := ele.Value.(*entry)
-		// 从字典中 c.cache 删除该节点的映射关系
-		delete(c.cache, kv.key)
-		// 更新当前所用的内存
-		c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
-		if c.OnEvicted != nil {
-			c.OnEvicted(kv.key, kv.value)
+		c.removeElement(ele)
+	}
+}
+
+// removeElement 把链表节点从 ll 和 cache 中删除，并触发 OnEvicted 回调。
+func (c *Cache) removeElement(ele *list.Element) {
+	c.ll.Remove(ele)
+	kv := ele.Value.(*entry)
+	// 从字典中 c.cache 删除该节点的映射关系
+	delete(c.cache, kv.key)
+	// 更新当前所用的内存
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
+
+// sweepExpired 是一个惰性的过期清理器，在每次 Add 时顺带回收已过期的条目。
+// 链表顺序只反映访问时间（LRU），不反映过期时间——单个 key 的 TTL 可以被
+// Add 的可变参数覆盖，一个短 TTL 的条目完全可能排在某个长 TTL 条目前面，
+// 所以不能只看队尾是否过期就停下来，必须整体扫一遍 c.cache。
+func (c *Cache) sweepExpired() {
+	for _, ele := range c.cache {
+		if ele.Value.(*entry).expired() {
+			c.removeElement(ele)
 		}
 	}
 }
 
-// Add :缓存的新增和修改
-func (c *Cache) Add(key string, value Value) {
+// Add :缓存的新增和修改，ttl 可选，不传则使用 New 时设置的 defaultTTL，传 0 表示永不过期。
+func (c *Cache) Add(key string, value Value, ttl ...time.Duration) {
+	expiry := c.defaultTTL
+	if len(ttl) > 0 {
+		expiry = ttl[0]
+	}
+	var expireAt time.Time
+	if expiry > 0 {
+		expireAt = time.Now().Add(expiry)
+	}
+
 	// 如果键存在，则更新对应节点的值，并将该节点移到队尾。
 	if ele, ok := c.cache[key]; ok {
 		// 将该节点移到队尾
@@ -78,20 +120,41 @@ func (c *Cache) Add(key string, value Value) {
 		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
 		// 更新该节点的值
 		kv.value = value
+		kv.expireAt = expireAt
 	} else {
 		// 不存在则在队尾新增节点
-		ele := c.ll.PushFront(&entry{key, value})
+		ele := c.ll.PushFront(&entry{key: key, value: value, expireAt: expireAt})
 		// 新增字典键值对
 		c.cache[key] = ele
 		c.nbytes += int64(len(key)) + int64(value.Len())
 	}
 
+	// 顺带清理所有已过期的条目
+	c.sweepExpired()
+
 	// 当前内存如果超过了设定的最大值，则移除最少访问的节点。
 	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
 		c.RemoveOldest()
 	}
 }
 
+// Remove :主动移除一个 key，不触发 OnEvicted（淘汰回调只用于容量/过期驱动的移除）
+func (c *Cache) Remove(key string) {
+	if ele, ok := c.cache[key]; ok {
+		c.ll.Remove(ele)
+		kv := ele.Value.(*entry)
+		delete(c.cache, kv.key)
+		c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	}
+}
+
+// Clear :清空整个缓存，用于批量失效的场景
+func (c *Cache) Clear() {
+	c.ll = list.New()
+	c.cache = make(map[string]*list.Element)
+	c.nbytes = 0
+}
+
 // Len :返回链表的大小 用于测试
 func (c *Cache) Len() int {
 	return c.ll.Len()
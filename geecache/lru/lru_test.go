@@ -0,0 +1,55 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+type stringValue string
+
+func (s stringValue) Len() int { return len(s) }
+
+// TestGetReturnsMissAfterExpiry 验证 TTL 的基本契约：一个条目的 expireAt 过去
+// 之后，Get 应当视为未命中（并顺带把它从缓存中淘汰），而不是继续返回旧值。
+func TestGetReturnsMissAfterExpiry(t *testing.T) {
+	c := New(0, 0, nil)
+	c.Add("k", stringValue("v"), 50*time.Millisecond)
+
+	if v, ok := c.Get("k"); !ok || v != stringValue("v") {
+		t.Fatalf("expected a hit before expiry, got %v, %v", v, ok)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expected a miss after the entry's TTL has passed")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected Get to evict the expired entry, Len() = %d", c.Len())
+	}
+}
+
+// TestMixedTTLSweepReclaimsExpiredRegardlessOfPosition 覆盖一个不按 LRU 顺序
+// 过期的场景：先添加一个长 TTL（或不过期）的条目，再添加一个短 TTL 的条目，
+// 短 TTL 的条目在链表里排在长 TTL 条目前面。等短 TTL 的条目过期后，
+// 触发下一次 Add 应当能够回收它，而不是因为它不在队尾就被跳过。
+func TestMixedTTLSweepReclaimsExpiredRegardlessOfPosition(t *testing.T) {
+	c := New(0, 0, nil)
+	c.Add("long", stringValue("a")) // 使用 defaultTTL=0，永不过期
+	c.Add("short", stringValue("b"), 50*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// 触发一次新的 Add，顺带清理过期条目。
+	c.Add("trigger", stringValue("c"))
+
+	if _, ok := c.Get("short"); ok {
+		t.Fatal("expected expired \"short\" entry to have been swept, but it's still present")
+	}
+	if _, ok := c.Get("long"); !ok {
+		t.Fatal("expected non-expiring \"long\" entry to survive the sweep")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries (long, trigger) to remain after sweeping the expired one, got %d", c.Len())
+	}
+}
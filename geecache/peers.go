@@ -1,5 +1,9 @@
 package geecache
 
+import (
+	pb "Group_Cache/geecache/geecachepb"
+)
+
 /*
 	我们进一步细化流程 ⑵：
 
@@ -17,7 +21,28 @@ type PeerPicker interface {
 }
 
 // PeerGetter 接口的 Get() 方法用于从对应 group 查找缓存值。PeerGetter 就对应于上述流程中的 HTTP 客户端。
+// in 携带请求的 group/key，out 由实现方填充查询到的缓存值，使用 geecachepb 定义的消息类型
+// 便于以 protobuf 二进制编码在节点间传输。
 type PeerGetter interface {
-	// Get 参数使用 geecachepb.pb.go 中的数据类型
-	Get(group string,key string) ([]byte,error)
+	Get(in *pb.Request, out *pb.Response) error
+}
+
+// PeerRegistry 是可选的节点发现接口，允许把静态配置替换为 etcd/consul 等
+// 外部服务发现来源。Subscribe 应当立即以当前的全量节点列表回调一次，
+// 此后每当集群成员发生变化（扩容/缩容）都重新回调，由调用方（如 HTTPPool）
+// 决定如何把新的节点列表应用到哈希环上。
+type PeerRegistry interface {
+	Subscribe(onChange func(peers []string)) error
+}
+
+// PeerIterator 由需要把操作广播给集群内所有节点（而不仅仅是按 key 选出的那一个）
+// 的 PeerPicker 实现，例如 Invalidate 需要通知每一个节点，而不只是权威节点。
+type PeerIterator interface {
+	AllPeers() []PeerGetter
+}
+
+// PeerInvalidator 由 PeerGetter 的实现可选地同时满足，用来把本地的失效/删除
+// 操作广播给其他节点。in.Key 为空表示失效整个 group，否则只失效单个 key。
+type PeerInvalidator interface {
+	Invalidate(in *pb.Request) error
 }
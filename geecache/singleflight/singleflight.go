@@ -1,6 +1,9 @@
 package singleflight
 
-import "sync"
+import (
+	"fmt"
+	"sync"
+)
 
 /*
 	WaitGroup 对象内部有一个计数器，最初从0开始，它有三个方法：
@@ -10,12 +13,22 @@ import "sync"
 	适合用于并发协程之间不需要消息传递的情况
 */
 
+// Result 是一次 Do/DoChan 调用的结果。Shared 表示这次调用是否与其他调用者
+// 共享了同一次 fn 执行（即发生了请求合并），调用方可以据此统计合并命中率。
+type Result struct {
+	Val    interface{}
+	Err    error
+	Shared bool
+}
+
 // call 代表正在进行中，或已经结束的请求
 type call struct {
-	// 避免重入
-	wg  sync.WaitGroup
 	val interface{}
 	err error
+	// dups 记录除发起者之外，还有多少调用者在等待这次 fn 的结果
+	dups int
+	// chans 是所有等待这次 fn 结果的调用者各自的回调 channel
+	chans []chan<- Result
 }
 
 // Group singleflight 的主数据结构，管理不同 key 的请求(call)
@@ -27,8 +40,16 @@ type Group struct {
 
 // Do 方法，接收 2 个参数，第一个参数是 key，第二个参数是一个函数 fn。
 // Do 的作用就是，针对相同的 key，无论 Do 被调用多少次，函数 fn 都只会被调用一次，
-// 等待 fn 调用结束了，返回返回值或错误。
+// 等待 fn 调用结束了，返回返回值或错误。Do 是 DoChan 的同步包装。
 func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	res := <-g.DoChan(key, fn)
+	return res.Val, res.Err
+}
+
+// DoChan 和 Do 作用相同，区别是它立即返回一个 channel，调用方可以异步地
+// 等待结果，而不必阻塞在 Do 里面。
+func (g *Group) DoChan(key string, fn func() (interface{}, error)) <-chan Result {
+	ch := make(chan Result, 1)
 
 	// 保护 Group 的成员变量 m 不被并发读写而加上的锁
 	g.mu.Lock()
@@ -38,36 +59,53 @@ func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, err
 	}
 
 	if c, ok := g.m[key]; ok {
+		// 请求正在进行中，把自己的 channel 挂到这次 call 上，等待它结束后一并通知
+		c.dups++
+		c.chans = append(c.chans, ch)
 		g.mu.Unlock()
-
-		// 如果请求正在进行中，则等待，直到计数器为0
-		c.wg.Wait()
-		// 请求结束，返回结果，结果直接使用之前请求查询的结果
-		// 即所有用户都能收到结果，请求是在服务端阻塞的
-		return c.val, c.err
+		return ch
 	}
 
-	// 若请求没在进行中，初始化
-	c := new(call)
-	// 发起请求前加锁 计数器加一
-	c.wg.Add(1)
-	// 添加到g.m，表明key已经有对应的请求在处理
+	// 若请求没在进行中，初始化并登记到 g.m，表明 key 已经有对应的请求在处理
+	c := &call{chans: []chan<- Result{ch}}
 	g.m[key] = c
-
 	g.mu.Unlock()
 
-	// 调用fn，发起请求
+	go g.doCall(c, key, fn)
+
+	return ch
+}
+
+// doCall 实际调用 fn，并把结果广播给所有等待这个 key 的调用者。
+// recover 放在这里是因为 fn 由用户提供，一旦 panic 且没有人兜底，
+// 所有正在等待结果的 follower 都会因为永远等不到 channel 写入而死锁。
+func (g *Group) doCall(c *call, key string, fn func() (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("singleflight: panic recovered: %v", r)
+		}
+
+		g.mu.Lock()
+		// 期间若被 Forget 过，g.m[key] 可能已是一次新的 call，不能把它删掉
+		if g.m[key] == c {
+			delete(g.m, key)
+		}
+		g.mu.Unlock()
+
+		shared := c.dups > 0
+		for _, ch := range c.chans {
+			ch <- Result{Val: c.val, Err: c.err, Shared: shared}
+		}
+	}()
+
 	c.val, c.err = fn()
-	// 请求结束 计数器减一
-	c.wg.Done()
+}
 
+// Forget 丢弃 key 对应的记录（如果存在），这样下一次 Do(key, fn) 会重新执行 fn，
+// 而不是等待/复用一个可能已经过期的结果。已经在执行中的 fn 不会被取消，
+// 它的结果仍会交给 Forget 之前就在等待的调用者。
+func (g *Group) Forget(key string) {
 	g.mu.Lock()
-	// 更新g.m
 	delete(g.m, key)
 	g.mu.Unlock()
-
-	// 返回结果
-	return c.val, c.err
 }
-
-
@@ -0,0 +1,102 @@
+package singleflight
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var g Group
+	v, err := g.Do("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+	if v != "bar" || err != nil {
+		t.Errorf("Do v = %v, err = %v", v, err)
+	}
+}
+
+// TestDoChanShared 验证并发请求同一个 key 时只执行一次 fn，
+// 且发生合并时跟随者收到的 Result.Shared 为 true（发起者收到的也是 true，这里只检查跟随者）。
+func TestDoChanShared(t *testing.T) {
+	var g Group
+	var calls int32
+	var mu sync.Mutex
+
+	release := make(chan struct{})
+	start := func() <-chan Result {
+		return g.DoChan("key", func() (interface{}, error) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			<-release
+			return "bar", nil
+		})
+	}
+
+	ch1 := start()
+	// 确保 ch1 对应的 fn 已经开始执行、并登记到 g.m 之后，ch2 才发起
+	time.Sleep(10 * time.Millisecond)
+	ch2 := start()
+
+	close(release)
+
+	res1 := <-ch1
+	res2 := <-ch2
+
+	if res1.Val != "bar" || res2.Val != "bar" {
+		t.Fatalf("unexpected values: %v, %v", res1.Val, res2.Val)
+	}
+	if calls != 1 {
+		t.Fatalf("expected fn to run exactly once, ran %d times", calls)
+	}
+	if !res2.Shared {
+		t.Fatalf("expected the second (follower) caller to observe Shared=true")
+	}
+}
+
+// TestDoPanicIsRecovered 确保一个 panic 的 loader 不会让 Do 的调用者永远卡住，
+// 而是得到一个非 nil 的 error。
+func TestDoPanicIsRecovered(t *testing.T) {
+	var g Group
+	done := make(chan struct{})
+
+	go func() {
+		_, err := g.Do("key", func() (interface{}, error) {
+			panic("boom")
+		})
+		if err == nil {
+			t.Error("expected an error after a panicking loader, got nil")
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Do did not return after the loader panicked, the caller is deadlocked")
+	}
+}
+
+// TestForget 验证 Forget 之后，下一次 Do 会重新执行 fn 而不是复用旧结果。
+func TestForget(t *testing.T) {
+	var g Group
+	var calls int
+
+	fn := func() (interface{}, error) {
+		calls++
+		return fmt.Sprintf("call-%d", calls), nil
+	}
+
+	v1, _ := g.Do("key", fn)
+	g.Forget("key")
+	v2, _ := g.Do("key", fn)
+
+	if v1 == v2 {
+		t.Fatalf("expected Forget to force a fresh call, got the same value twice: %v", v1)
+	}
+	if calls != 2 {
+		t.Fatalf("expected fn to run twice, ran %d times", calls)
+	}
+}
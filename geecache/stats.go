@@ -0,0 +1,33 @@
+package geecache
+
+import "sync/atomic"
+
+// Stats 记录了一个 Group 的运行时指标，供运维观察缓存命中、远程拉取等分布情况。
+// 所有字段都通过原子操作读写，可以在不加锁的情况下被多个 goroutine 并发访问。
+type Stats struct {
+	Gets       int64 // Get 被调用的总次数
+	CacheHits  int64 // 命中 mainCache 或 hotCache 的次数
+	PeerLoads  int64 // 从远程节点成功加载的次数
+	PeerErrors int64 // 从远程节点加载失败的次数
+	LocalLoads int64 // 回退到本地 getter 加载的次数
+	LoaderHits int64 // 命中 singleflight，与其他请求共享同一次加载结果的次数
+}
+
+func (s *Stats) addGets()       { atomic.AddInt64(&s.Gets, 1) }
+func (s *Stats) addCacheHits()  { atomic.AddInt64(&s.CacheHits, 1) }
+func (s *Stats) addPeerLoads()  { atomic.AddInt64(&s.PeerLoads, 1) }
+func (s *Stats) addPeerErrors() { atomic.AddInt64(&s.PeerErrors, 1) }
+func (s *Stats) addLocalLoads() { atomic.AddInt64(&s.LocalLoads, 1) }
+func (s *Stats) addLoaderHits() { atomic.AddInt64(&s.LoaderHits, 1) }
+
+// snapshot 返回 Stats 的一份拷贝，避免调用方持有的指针被后续写入影响。
+func (s *Stats) snapshot() Stats {
+	return Stats{
+		Gets:       atomic.LoadInt64(&s.Gets),
+		CacheHits:  atomic.LoadInt64(&s.CacheHits),
+		PeerLoads:  atomic.LoadInt64(&s.PeerLoads),
+		PeerErrors: atomic.LoadInt64(&s.PeerErrors),
+		LocalLoads: atomic.LoadInt64(&s.LocalLoads),
+		LoaderHits: atomic.LoadInt64(&s.LoaderHits),
+	}
+}
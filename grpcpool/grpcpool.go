@@ -0,0 +1,214 @@
+package grpcpool
+
+/*
+	grpcpool 是 HTTPPool 的 gRPC 版本：节点间同样使用一致性哈希选择对端，
+	同样实现 geecache.PeerPicker / geecache.PeerGetter，只是底层传输换成了
+	长连接、多路复用的 gRPC，省去了 httpGetter.Get 里每次请求都走一遍
+	http.Get 的开销，并且可以借助 grpc.DialOption/grpc.ServerOption 启用 mTLS。
+
+	用法与 HTTPPool 基本一致。grpc.Dial 要求显式给出传输安全选项，
+	不传 WithDialOptions 时连接对端会直接失败：
+
+		pool := grpcpool.NewGRPCPool(self, grpcpool.WithDialOptions(grpc.WithInsecure()))
+		pool.Set(addrs...)
+		gee.RegisterPeers(pool)
+		log.Fatal(pool.ListenAndServe(addr))
+*/
+
+import (
+	"Group_Cache/geecache"
+	"Group_Cache/geecache/consistenthash"
+	pb "Group_Cache/geecache/geecachepb"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+const defaultReplicas = 50
+
+// Option 用于配置 GRPCPool，遵循函数式选项模式。
+type Option func(*GRPCPool)
+
+// WithDialOptions 为连接其他节点时使用的 grpc.Dial 附加选项，
+// 例如 grpc.WithTransportCredentials(...) 以启用节点间的 mTLS。
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(p *GRPCPool) {
+		p.dialOpts = append(p.dialOpts, opts...)
+	}
+}
+
+// GRPCPool 承载节点间基于 gRPC 的通信，兼具 geecache.PeerPicker（选择对端）
+// 和 pb.GroupCacheServer（响应对端的查询请求）两个角色。
+type GRPCPool struct {
+	pb.UnimplementedGroupCacheServer
+
+	// self 是本节点地址，例如 "localhost:9001"
+	self string
+
+	mu    sync.RWMutex
+	peers *consistenthash.Map
+	// clients 映射远程节点地址与对应的 grpcGetter，每个远程节点复用一条长连接。
+	clients map[string]*grpcGetter
+
+	dialOpts []grpc.DialOption
+}
+
+// NewGRPCPool 初始化一个 GRPCPool
+func NewGRPCPool(self string, opts ...Option) *GRPCPool {
+	p := &GRPCPool{self: self}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Log 带有服务器名称的日志信息
+func (p *GRPCPool) Log(format string, v ...interface{}) {
+	log.Printf("[Server %s] %s", p.self, fmt.Sprintf(format, v...))
+}
+
+// Set 实例化一致性哈希算法，并整体重建所有节点，适合启动时一次性配置。
+func (p *GRPCPool) Set(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.peers = consistenthash.New(defaultReplicas, nil)
+	p.peers.Add(peers...)
+
+	p.closeClientsLocked()
+	p.clients = make(map[string]*grpcGetter, len(peers))
+	for _, peer := range peers {
+		p.clients[peer] = &grpcGetter{addr: peer, dialOpts: p.dialOpts}
+	}
+}
+
+// Add 增量添加节点，只有落在新节点上的 key 会被重新映射。
+func (p *GRPCPool) Add(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		p.peers = consistenthash.New(defaultReplicas, nil)
+		p.clients = make(map[string]*grpcGetter)
+	}
+	p.peers.Add(peers...)
+	for _, peer := range peers {
+		if _, ok := p.clients[peer]; !ok {
+			p.clients[peer] = &grpcGetter{addr: peer, dialOpts: p.dialOpts}
+		}
+	}
+}
+
+// Remove 增量摘除节点，支持集群在线缩容。
+func (p *GRPCPool) Remove(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return
+	}
+	for _, peer := range peers {
+		p.peers.Remove(peer)
+		if c, ok := p.clients[peer]; ok {
+			c.close()
+			delete(p.clients, peer)
+		}
+	}
+}
+
+// closeClientsLocked 关闭已有的所有客户端连接，调用方需持有 p.mu。
+func (p *GRPCPool) closeClientsLocked() {
+	for _, c := range p.clients {
+		c.close()
+	}
+}
+
+// PickPeer 实现 geecache.PeerPicker 接口
+func (p *GRPCPool) PickPeer(key string) (geecache.PeerGetter, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	if peer := p.peers.Get(key); peer != "" && peer != p.self {
+		p.Log("Pick peer %s", peer)
+		return p.clients[peer], true
+	}
+	return nil, false
+}
+
+// Get 实现 pb.GroupCacheServer 接口，是 HTTPPool.ServeHTTP 的 gRPC 等价物，
+// 两者最终都通过 Group.serveGet 完成查找，这里经由 geecache.ServeGet 调用。
+func (p *GRPCPool) Get(ctx context.Context, req *pb.Request) (*pb.Response, error) {
+	p.Log("%s/%s", req.GetGroup(), req.GetKey())
+	return geecache.ServeGet(req)
+}
+
+// ListenAndServe 在 addr 上启动一个 gRPC server 并注册本节点，阻塞直到出错。
+func (p *GRPCPool) ListenAndServe(addr string, opts ...grpc.ServerOption) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer(opts...)
+	pb.RegisterGroupCacheServer(s, p)
+	return s.Serve(lis)
+}
+
+// grpcGetter 实现 geecache.PeerGetter 接口，对应一个远程节点的 gRPC 客户端。
+// 连接在首次使用时建立并一直复用，多个请求在同一条连接上多路复用。
+type grpcGetter struct {
+	addr     string
+	dialOpts []grpc.DialOption
+
+	mu     sync.Mutex
+	conn   *grpc.ClientConn
+	client pb.GroupCacheClient
+}
+
+func (g *grpcGetter) getClient() (pb.GroupCacheClient, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.client != nil {
+		return g.client, nil
+	}
+	conn, err := grpc.Dial(g.addr, g.dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %v", g.addr, err)
+	}
+	g.conn = conn
+	g.client = pb.NewGroupCacheClient(conn)
+	return g.client, nil
+}
+
+// Get 实现 geecache.PeerGetter 接口
+func (g *grpcGetter) Get(in *pb.Request, out *pb.Response) error {
+	client, err := g.getClient()
+	if err != nil {
+		return err
+	}
+	res, err := client.Get(context.Background(), in)
+	if err != nil {
+		return err
+	}
+	out.Value = res.Value
+	return nil
+}
+
+func (g *grpcGetter) close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn != nil {
+		g.conn.Close()
+		g.conn = nil
+		g.client = nil
+	}
+}
+
+// 确保这些类型实现了对应的接口，没有实现会在编译期报错
+var (
+	_ geecache.PeerPicker = (*GRPCPool)(nil)
+	_ geecache.PeerGetter = (*grpcGetter)(nil)
+	_ pb.GroupCacheServer = (*GRPCPool)(nil)
+)